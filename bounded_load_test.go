@@ -0,0 +1,85 @@
+package microhash
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHash_GetWithLoadSpreadsHotKey(t *testing.T) {
+	const (
+		nodeCount   = 5
+		maxLoad     = 1.25
+		assignments = 500
+		hotKey      = "same-key"
+	)
+
+	ch := New(WithMaxLoadFactor(maxLoad))
+	for i := 0; i < nodeCount; i++ {
+		ch.Add(localhostPrefix + strconv.Itoa(i))
+	}
+
+	plainNode, ok := ch.Get(hotKey)
+	assert.True(t, ok)
+
+	counts := make(map[any]int)
+
+	for i := 0; i < assignments; i++ {
+		node, _, ok := ch.GetWithLoad(hotKey)
+		assert.True(t, ok)
+		counts[node]++
+
+		again, ok := ch.Get(hotKey)
+		assert.True(t, ok)
+		assert.Equal(t, plainNode, again)
+	}
+
+	assert.True(t, len(counts) > 1, "GetWithLoad should spread a hot key across more than one node")
+
+	avg := float64(assignments) / float64(nodeCount)
+	for node, count := range counts {
+		assert.True(t, float64(count) <= avg*maxLoad+1,
+			fmt.Sprintf("node %v got %d assignments, avg %f, max load factor %f", node, count, avg, maxLoad))
+	}
+}
+
+func TestConsistentHash_GetWithLoadRelease(t *testing.T) {
+	ch := New(WithMaxLoadFactor(1.25))
+	ch.Add("node")
+
+	node, done, ok := ch.GetWithLoad("key")
+	assert.True(t, ok)
+	assert.Equal(t, "node", node)
+	assert.Equal(t, int64(1), atomic.LoadInt64(ch.loads[repr(node)]))
+
+	done()
+	assert.Equal(t, int64(0), atomic.LoadInt64(ch.loads[repr(node)]))
+
+	// Releasing twice must not drive the counter negative.
+	done()
+	assert.Equal(t, int64(0), atomic.LoadInt64(ch.loads[repr(node)]))
+}
+
+func TestConsistentHash_Done(t *testing.T) {
+	ch := New()
+	ch.Add("node")
+
+	_, _, ok := ch.GetWithLoad("key")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), atomic.LoadInt64(ch.loads["node"]))
+
+	ch.Done("node")
+	assert.Equal(t, int64(0), atomic.LoadInt64(ch.loads["node"]))
+}
+
+func TestConsistentHash_GetWithLoadNoNodes(t *testing.T) {
+	ch := New()
+
+	node, done, ok := ch.GetWithLoad("key")
+	assert.False(t, ok)
+	assert.Nil(t, node)
+	assert.Nil(t, done)
+}
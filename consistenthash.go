@@ -0,0 +1,420 @@
+package microhash
+
+import (
+	"fmt"
+	"hash/crc64"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// topWeight is the weight assigned by Add, the full weight a node can carry.
+	topWeight = 100
+
+	minReplicas = 100
+
+	// defaultMaxLoadFactor is used when WithMaxLoadFactor is not supplied to
+	// New/NewWithCustomHash. A factor below 1.0 would make bounded lookups
+	// impossible to satisfy, so GetWithLoad treats it as "unbounded".
+	defaultMaxLoadFactor = 0
+)
+
+type (
+	// Func defines the hash method used to place nodes and keys on the ring.
+	Func func(data []byte) uint64
+
+	// Option customizes a ConsistentHash created via New or NewWithCustomHash.
+	Option func(h *ConsistentHash)
+
+	// ConsistentHash maps keys onto nodes arranged on a hash ring. Each node
+	// is replicated many times (virtual nodes) so that removing or adding a
+	// node only reshuffles a small fraction of the keyspace.
+	ConsistentHash struct {
+		hashFunc      Func
+		replicas      int
+		maxLoadFactor float64
+		keys          []uint64
+		ring          map[uint64][]any
+		nodes         map[string]struct{}
+		weights       map[string]int
+		loads         map[string]*int64
+		totalLoad     int64
+		lock          sync.RWMutex
+	}
+)
+
+// New returns a ConsistentHash using the default replica count and hash func.
+func New(opts ...Option) *ConsistentHash {
+	return NewWithCustomHash(minReplicas, nil, opts...)
+}
+
+// NewWithCustomHash returns a ConsistentHash with the given replica count and
+// hash function. If fn is nil, a built-in hash is used. If replicas is lower
+// than minReplicas, minReplicas is used instead so that small rings still get
+// a reasonable distribution.
+func NewWithCustomHash(replicas int, fn Func, opts ...Option) *ConsistentHash {
+	if replicas < minReplicas {
+		replicas = minReplicas
+	}
+
+	if fn == nil {
+		fn = hashValue
+	}
+
+	h := &ConsistentHash{
+		hashFunc:      fn,
+		replicas:      replicas,
+		maxLoadFactor: defaultMaxLoadFactor,
+		ring:          make(map[uint64][]any),
+		nodes:         make(map[string]struct{}),
+		weights:       make(map[string]int),
+		loads:         make(map[string]*int64),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// WithMaxLoadFactor sets the load factor c used by GetWithLoad: a node is
+// considered eligible for a key as long as its current load stays under
+// avg*c, where avg is ceil((totalLoad+1)/numNodes), the average load once
+// the key being looked up is assigned. c must be >= 1.0; typical values are
+// in the 1.1-1.5 range.
+func WithMaxLoadFactor(c float64) Option {
+	return func(h *ConsistentHash) {
+		h.maxLoadFactor = c
+	}
+}
+
+// Add adds a node to the ring with the full weight.
+func (h *ConsistentHash) Add(node any) {
+	h.AddWithWeight(node, topWeight)
+}
+
+// AddWithWeight adds a node to the ring with a weight between 1 and
+// topWeight, proportionally scaling the number of replicas it gets.
+func (h *ConsistentHash) AddWithWeight(node any, weight int) {
+	replicas := h.replicas * weight / topWeight
+	h.AddWithReplicas(node, replicas)
+}
+
+// AddWithReplicas adds a node to the ring with an explicit replica count,
+// replacing any previous replicas for the same node.
+func (h *ConsistentHash) AddWithReplicas(node any, replicas int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.removeNode(node)
+
+	nodeRepr := repr(node)
+	for i := 0; i < replicas; i++ {
+		hash := h.hashFunc([]byte(nodeRepr + strconv.Itoa(i)))
+		h.keys = append(h.keys, hash)
+		h.ring[hash] = append(h.ring[hash], node)
+	}
+
+	sort.Slice(h.keys, func(i, j int) bool {
+		return h.keys[i] < h.keys[j]
+	})
+
+	h.nodes[nodeRepr] = struct{}{}
+	h.loads[nodeRepr] = new(int64)
+
+	weight := replicas * topWeight / h.replicas
+	if weight < 1 {
+		weight = 1
+	}
+
+	h.weights[nodeRepr] = weight
+}
+
+// Remove removes a node and all its replicas from the ring.
+func (h *ConsistentHash) Remove(node any) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.removeNode(node)
+}
+
+// Get returns the node a key maps to on the ring.
+func (h *ConsistentHash) Get(v any) (any, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.ring) == 0 {
+		return nil, false
+	}
+
+	index := h.search(h.hashFunc([]byte(repr(v))))
+	node := h.pick(h.ring[h.keys[index]], v)
+
+	return node, node != nil
+}
+
+// GetN returns up to n distinct underlying nodes for key, walking the ring
+// clockwise from key's position and collecting each node the first time one
+// of its replicas is seen. It's meant for replica placement: the first
+// element is always what Get would return for the same key, and the rest
+// are fallback/replica targets in ring order. If n is at least the number
+// of distinct nodes on the ring, every node is returned.
+func (h *ConsistentHash) GetN(key any, n int) ([]any, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.keys) == 0 || n <= 0 {
+		return nil, false
+	}
+
+	if n > len(h.nodes) {
+		n = len(h.nodes)
+	}
+
+	result := make([]any, 0, n)
+	seen := make(map[string]struct{}, n)
+
+	add := func(node any) bool {
+		nodeRepr := repr(node)
+		if _, ok := seen[nodeRepr]; ok {
+			return false
+		}
+
+		seen[nodeRepr] = struct{}{}
+		result = append(result, node)
+
+		return len(result) == n
+	}
+
+	index := h.search(h.hashFunc([]byte(repr(key))))
+	firstSlot := h.ring[h.keys[index]]
+
+	if add(h.pick(firstSlot, key)) {
+		return result, true
+	}
+
+	for _, node := range firstSlot {
+		if add(node) {
+			return result, true
+		}
+	}
+
+	for i := 1; i < len(h.keys); i++ {
+		for _, node := range h.ring[h.keys[(index+i)%len(h.keys)]] {
+			if add(node) {
+				return result, true
+			}
+		}
+	}
+
+	return result, true
+}
+
+// pick selects one node out of a ring slot. Slots hold more than one node
+// only when two distinct nodes' replicas hash to the same value, which is
+// rare; when that happens the choice is derived from v so it stays stable
+// across calls.
+func (h *ConsistentHash) pick(nodes []any, v any) any {
+	switch len(nodes) {
+	case 0:
+		return nil
+	case 1:
+		return nodes[0]
+	default:
+		innerIndex := h.hashFunc([]byte("inner" + repr(v)))
+		pos := int(innerIndex % uint64(len(nodes)))
+
+		return nodes[pos]
+	}
+}
+
+// GetWithLoad returns a node for the key the same way Get does, but skips
+// nodes whose current load has reached avg*c (see WithMaxLoadFactor),
+// walking the ring clockwise until it finds one that hasn't. The caller must
+// invoke the returned release func (or Done) once it's finished with the
+// node, so the load can be handed to someone else. If every reachable node
+// is at capacity, the least loaded one among them is returned instead.
+func (h *ConsistentHash) GetWithLoad(key any) (node any, done func(), ok bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.keys) == 0 {
+		return nil, nil, false
+	}
+
+	avg := h.avgLoad()
+	index := h.search(h.hashFunc([]byte(repr(key))))
+
+	var (
+		fallback     any
+		fallbackLoad int64 = math.MaxInt64
+		fallbackRepr string
+		visited      = make(map[string]struct{}, len(h.nodes))
+	)
+
+	for i := 0; i < len(h.keys); i++ {
+		candidates := h.ring[h.keys[(index+i)%len(h.keys)]]
+		for _, candidate := range candidates {
+			candidateRepr := repr(candidate)
+			if _, seen := visited[candidateRepr]; seen {
+				continue
+			}
+
+			visited[candidateRepr] = struct{}{}
+			load := atomic.LoadInt64(h.loads[candidateRepr])
+
+			if h.maxLoadFactor < 1 || float64(load) < avg*h.maxLoadFactor {
+				return candidate, h.releaseFunc(candidateRepr), true
+			}
+
+			if load < fallbackLoad {
+				fallback, fallbackLoad, fallbackRepr = candidate, load, candidateRepr
+			}
+		}
+
+		if len(visited) == len(h.nodes) {
+			break
+		}
+	}
+
+	if fallback == nil {
+		return nil, nil, false
+	}
+
+	return fallback, h.releaseFunc(fallbackRepr), true
+}
+
+// Done releases a load unit previously acquired for node via GetWithLoad.
+// It is a no-op if node is not currently on the ring.
+func (h *ConsistentHash) Done(node any) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	h.release(repr(node))
+}
+
+func (h *ConsistentHash) releaseFunc(nodeRepr string) func() {
+	atomic.AddInt64(h.loads[nodeRepr], 1)
+	atomic.AddInt64(&h.totalLoad, 1)
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			h.lock.RLock()
+			defer h.lock.RUnlock()
+
+			h.release(nodeRepr)
+		})
+	}
+}
+
+func (h *ConsistentHash) release(nodeRepr string) {
+	counter, ok := h.loads[nodeRepr]
+	if !ok {
+		return
+	}
+
+	atomic.AddInt64(counter, -1)
+	atomic.AddInt64(&h.totalLoad, -1)
+}
+
+// avgLoad returns ceil((totalLoad+1) / numNodes), the average load each node
+// should carry once one more key is assigned. Caller must hold h.lock.
+func (h *ConsistentHash) avgLoad() float64 {
+	if len(h.nodes) == 0 {
+		return 0
+	}
+
+	total := atomic.LoadInt64(&h.totalLoad)
+
+	return math.Ceil(float64(total+1) / float64(len(h.nodes)))
+}
+
+// search returns the index into h.keys of the first key at or after hash,
+// wrapping around to 0 when hash is past the last key on the ring.
+func (h *ConsistentHash) search(hash uint64) int {
+	index := sort.Search(len(h.keys), func(i int) bool {
+		return h.keys[i] >= hash
+	})
+
+	if index >= len(h.keys) {
+		index = 0
+	}
+
+	return index
+}
+
+func (h *ConsistentHash) removeNode(node any) {
+	nodeRepr := repr(node)
+	if _, ok := h.nodes[nodeRepr]; !ok {
+		return
+	}
+
+	delete(h.nodes, nodeRepr)
+	delete(h.weights, nodeRepr)
+	delete(h.loads, nodeRepr)
+
+	for hash, nodes := range h.ring {
+		remaining := nodes[:0]
+		for _, n := range nodes {
+			if repr(n) != nodeRepr {
+				remaining = append(remaining, n)
+			}
+		}
+
+		if len(remaining) > 0 {
+			h.ring[hash] = remaining
+		} else {
+			delete(h.ring, hash)
+
+			index := sort.Search(len(h.keys), func(i int) bool {
+				return h.keys[i] >= hash
+			})
+			if index < len(h.keys) && h.keys[index] == hash {
+				h.keys = append(h.keys[:index], h.keys[index+1:]...)
+			}
+		}
+	}
+}
+
+// repr renders v the way Add/Get hash it: via its String method when it
+// implements fmt.Stringer, as raw bytes for []byte, via Error() for errors,
+// and via fmt's default formatting otherwise.
+func repr(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	switch vt := v.(type) {
+	case fmt.Stringer:
+		return vt.String()
+	case []byte:
+		return string(vt)
+	case error:
+		return vt.Error()
+	default:
+		return fmt.Sprintf("%v", vt)
+	}
+}
+
+var crcTable = crc64.MakeTable(crc64.ISO)
+
+// hashValue is the default Func: a 64-bit CRC passed through the splitmix64
+// finalizer. The CRC alone leaves short, similar inputs ("1000", "1001", ...)
+// clustered on the ring; the finalizer spreads them evenly without pulling in
+// an external hashing dependency.
+func hashValue(data []byte) uint64 {
+	x := crc64.Checksum(data, crcTable)
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
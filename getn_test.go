@@ -0,0 +1,86 @@
+package microhash
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHash_GetNMatchesGet(t *testing.T) {
+	ch := New()
+	for i := 0; i < keySize; i++ {
+		ch.Add(localhostPrefix + strconv.Itoa(i))
+	}
+
+	for i := 0; i < requestSize; i++ {
+		primary, ok := ch.Get(i)
+		assert.True(t, ok)
+
+		nodes, ok := ch.GetN(i, 3)
+		assert.True(t, ok)
+		assert.True(t, len(nodes) > 0)
+		assert.Equal(t, primary, nodes[0])
+	}
+}
+
+func TestConsistentHash_GetNDistinctAndStable(t *testing.T) {
+	ch := New()
+	for i := 0; i < keySize; i++ {
+		ch.Add(localhostPrefix + strconv.Itoa(i))
+	}
+
+	const n = 5
+
+	first, ok := ch.GetN("some-key", n)
+	assert.True(t, ok)
+	assert.Equal(t, n, len(first))
+
+	seen := make(map[any]struct{}, n)
+	for _, node := range first {
+		_, dup := seen[node]
+		assert.False(t, dup)
+		seen[node] = struct{}{}
+	}
+
+	again, ok := ch.GetN("some-key", n)
+	assert.True(t, ok)
+	assert.Equal(t, first, again)
+}
+
+func TestConsistentHash_GetNMoreThanNodes(t *testing.T) {
+	ch := New()
+	ch.Add("a")
+	ch.Add("b")
+
+	nodes, ok := ch.GetN("any", 10)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(nodes))
+}
+
+func TestConsistentHash_GetNNoNodes(t *testing.T) {
+	ch := New()
+
+	nodes, ok := ch.GetN("any", 3)
+	assert.False(t, ok)
+	assert.Nil(t, nodes)
+}
+
+func TestConsistentHash_GetNRemovePrimaryShiftsTail(t *testing.T) {
+	ch := New()
+	for i := 0; i < keySize; i++ {
+		ch.Add(localhostPrefix + strconv.Itoa(i))
+	}
+
+	const n = 4
+
+	before, ok := ch.GetN("some-key", n)
+	assert.True(t, ok)
+
+	ch.Remove(before[0])
+
+	after, ok := ch.GetN("some-key", n)
+	assert.True(t, ok)
+	assert.Equal(t, n, len(after))
+	assert.Equal(t, before[1:], after[:n-1])
+}
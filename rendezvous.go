@@ -0,0 +1,102 @@
+package microhash
+
+import (
+	"math"
+	"sync"
+)
+
+type rendezvousNode struct {
+	node   any
+	weight int
+}
+
+// RendezvousHash assigns each key to the node that scores highest under
+// hash(nodeID || key) (highest random weight hashing). Unlike ConsistentHash
+// it keeps no ring of virtual nodes, so adding or removing a node only
+// changes that one node's score computation: membership changes move the
+// provably minimal number of keys, at the cost of an O(numNodes) scan per
+// Get instead of an O(log numNodes) ring lookup.
+type RendezvousHash struct {
+	hashFunc Func
+	nodes    map[string]*rendezvousNode
+	lock     sync.RWMutex
+}
+
+// NewRendezvous returns a RendezvousHash using the default hash func.
+func NewRendezvous() *RendezvousHash {
+	return NewRendezvousWithHash(nil)
+}
+
+// NewRendezvousWithHash returns a RendezvousHash that scores nodes with fn.
+// If fn is nil, the built-in hash is used.
+func NewRendezvousWithHash(fn Func) *RendezvousHash {
+	if fn == nil {
+		fn = hashValue
+	}
+
+	return &RendezvousHash{
+		hashFunc: fn,
+		nodes:    make(map[string]*rendezvousNode),
+	}
+}
+
+// Add adds a node with the full weight.
+func (h *RendezvousHash) Add(node any) {
+	h.AddWithWeight(node, topWeight)
+}
+
+// AddWithWeight adds a node with the given weight, replacing any previous
+// weight recorded for the same node.
+func (h *RendezvousHash) AddWithWeight(node any, weight int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.nodes[repr(node)] = &rendezvousNode{node: node, weight: weight}
+}
+
+// Remove removes a node.
+func (h *RendezvousHash) Remove(node any) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.nodes, repr(node))
+}
+
+// Get returns the node that scores highest for key.
+func (h *RendezvousHash) Get(key any) (any, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.nodes) == 0 {
+		return nil, false
+	}
+
+	keyRepr := repr(key)
+
+	var (
+		best      any
+		bestScore = math.Inf(-1)
+	)
+
+	for nodeRepr, n := range h.nodes {
+		if score := h.score(nodeRepr, keyRepr, n.weight); score > bestScore {
+			best, bestScore = n.node, score
+		}
+	}
+
+	return best, true
+}
+
+// score computes the weighted rendezvous score of a node for a key: the
+// higher the score, the stronger the node's claim on the key.
+func (h *RendezvousHash) score(nodeRepr, keyRepr string, weight int) float64 {
+	p := hash01(h.hashFunc([]byte(nodeRepr + keyRepr)))
+
+	return -float64(weight) / math.Log(p)
+}
+
+// hash01 maps a uint64 hash onto (0, 1], never returning exactly 0 so
+// math.Log never sees a zero argument.
+func hash01(hash uint64) float64 {
+	return (float64(hash) + 1) / (float64(math.MaxUint64) + 1)
+}
@@ -0,0 +1,64 @@
+package microhash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRendezvousHash_Get(t *testing.T) {
+	h := NewRendezvous()
+
+	val, ok := h.Get("any")
+	assert.False(t, ok)
+	assert.Nil(t, val)
+
+	h.Add("node")
+
+	val, ok = h.Get("any")
+	assert.True(t, ok)
+	assert.Equal(t, "node", val)
+}
+
+func TestRendezvousHash_Deterministic(t *testing.T) {
+	h := NewRendezvous()
+	h.Add("a")
+	h.Add("b")
+	h.Add("c")
+
+	first, ok := h.Get("some-key")
+	assert.True(t, ok)
+
+	for i := 0; i < 100; i++ {
+		again, ok := h.Get("some-key")
+		assert.True(t, ok)
+		assert.Equal(t, first, again)
+	}
+}
+
+func TestRendezvousHash_Remove(t *testing.T) {
+	h := NewRendezvous()
+	h.Add("first")
+	h.Add("second")
+	h.Remove("first")
+
+	for i := 0; i < 100; i++ {
+		val, ok := h.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, "second", val)
+	}
+
+	h.Remove("second")
+
+	val, ok := h.Get(true)
+	assert.False(t, ok)
+	assert.Nil(t, val)
+}
+
+func TestRendezvousHash_AddWithWeightOverridesPreviousWeight(t *testing.T) {
+	h := NewRendezvous()
+	h.AddWithWeight("node", 1)
+	h.AddWithWeight("node", topWeight)
+
+	assert.Equal(t, topWeight, h.nodes[repr("node")].weight)
+}
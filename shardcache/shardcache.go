@@ -0,0 +1,214 @@
+// Package shardcache distributes a key-value cache across a set of backing
+// stores using consistent hashing, so adding or removing a store only
+// reshuffles a small fraction of the keyspace.
+package shardcache
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/DTreshy/microhash"
+)
+
+// ErrNoNode is returned when a key can't be routed because ShardedCache has
+// no nodes.
+var ErrNoNode = errors.New("shardcache: no node available")
+
+// Node is a single backing store a ShardedCache can route keys to.
+type Node interface {
+	Get(key string) ([]byte, error)
+	Set(key string, val []byte, ttl time.Duration) error
+	Del(keys ...string) error
+}
+
+// ShardedCache routes keys to a set of Nodes via consistent hashing.
+type ShardedCache struct {
+	hash  *microhash.ConsistentHash
+	lock  sync.RWMutex
+	nodes map[string]Node
+}
+
+// New returns an empty ShardedCache.
+func New() *ShardedCache {
+	return &ShardedCache{
+		hash:  microhash.New(),
+		nodes: make(map[string]Node),
+	}
+}
+
+// AddNode registers node under id, making it eligible to receive keys.
+// Calling AddNode again with an id already in use replaces that node.
+func (c *ShardedCache) AddNode(id string, node Node) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.nodes[id] = node
+	c.hash.Add(id)
+}
+
+// RemoveNode unregisters the node at id; its keys are routed to the
+// remaining nodes starting with the next call.
+func (c *ShardedCache) RemoveNode(id string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.nodes, id)
+	c.hash.Remove(id)
+}
+
+// Get returns the value stored for key on the node it's routed to.
+func (c *ShardedCache) Get(key string) ([]byte, error) {
+	node, err := c.nodeFor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.Get(key)
+}
+
+// Set stores val for key, with ttl, on the node it's routed to.
+func (c *ShardedCache) Set(key string, val []byte, ttl time.Duration) error {
+	node, err := c.nodeFor(key)
+	if err != nil {
+		return err
+	}
+
+	return node.Set(key, val, ttl)
+}
+
+// Del deletes key from the node it's routed to.
+func (c *ShardedCache) Del(key string) error {
+	node, err := c.nodeFor(key)
+	if err != nil {
+		return err
+	}
+
+	return node.Del(key)
+}
+
+// MGet fetches keys, grouping them by the node each one is routed to so
+// every backing node is served by a single goroutine. Node exposes no
+// batched read, so the keys routed to a given node are still fetched one at
+// a time, but nodes are queried concurrently with each other. A key whose
+// Get fails (including a key the node doesn't have) is absent from the
+// result map, and the first such error is returned alongside the map.
+func (c *ShardedCache) MGet(keys ...string) (map[string][]byte, error) {
+	groups, err := c.groupByNode(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		result   = make(map[string][]byte, len(keys))
+		firstErr error
+	)
+
+	for node, nodeKeys := range groups {
+		wg.Add(1)
+
+		go func(node Node, keys []string) {
+			defer wg.Done()
+
+			for _, key := range keys {
+				val, err := node.Get(key)
+
+				lock.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					result[key] = val
+				}
+				lock.Unlock()
+			}
+		}(node, nodeKeys)
+	}
+
+	wg.Wait()
+
+	return result, firstErr
+}
+
+// MDel deletes keys, grouping them by the node each one is routed to so
+// every backing node receives a single batched Del call.
+func (c *ShardedCache) MDel(keys ...string) error {
+	groups, err := c.groupByNode(keys)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		firstErr error
+	)
+
+	for node, nodeKeys := range groups {
+		wg.Add(1)
+
+		go func(node Node, keys []string) {
+			defer wg.Done()
+
+			if err := node.Del(keys...); err != nil {
+				lock.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				lock.Unlock()
+			}
+		}(node, nodeKeys)
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
+
+func (c *ShardedCache) nodeFor(key string) (Node, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	id, ok := c.hash.Get(key)
+	if !ok {
+		return nil, ErrNoNode
+	}
+
+	node, ok := c.nodes[id.(string)]
+	if !ok {
+		return nil, ErrNoNode
+	}
+
+	return node, nil
+}
+
+// groupByNode buckets keys by the Node each one is routed to.
+func (c *ShardedCache) groupByNode(keys []string) (map[Node][]string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if len(c.nodes) == 0 {
+		return nil, ErrNoNode
+	}
+
+	groups := make(map[Node][]string)
+
+	for _, key := range keys {
+		id, ok := c.hash.Get(key)
+		if !ok {
+			return nil, ErrNoNode
+		}
+
+		node, ok := c.nodes[id.(string)]
+		if !ok {
+			return nil, ErrNoNode
+		}
+
+		groups[node] = append(groups[node], key)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,186 @@
+package shardcache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockNode is an in-memory Node that also counts how many times Del was
+// called, so tests can check that MDel dispatches a single batched call per
+// node instead of one call per key.
+type mockNode struct {
+	id string
+
+	lock     sync.Mutex
+	data     map[string][]byte
+	delCalls int
+}
+
+func newMockNode(id string) *mockNode {
+	return &mockNode{
+		id:   id,
+		data: make(map[string][]byte),
+	}
+}
+
+func (n *mockNode) Get(key string) ([]byte, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	val, ok := n.data[key]
+	if !ok {
+		return nil, fmt.Errorf("shardcache: key %q not found on %s", key, n.id)
+	}
+
+	return val, nil
+}
+
+func (n *mockNode) Set(key string, val []byte, _ time.Duration) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.data[key] = val
+
+	return nil
+}
+
+func (n *mockNode) Del(keys ...string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.delCalls++
+	for _, key := range keys {
+		delete(n.data, key)
+	}
+
+	return nil
+}
+
+func newCache(nodeCount int) (*ShardedCache, []*mockNode) {
+	c := New()
+	nodes := make([]*mockNode, nodeCount)
+
+	for i := 0; i < nodeCount; i++ {
+		id := fmt.Sprintf("node-%d", i)
+		node := newMockNode(id)
+		nodes[i] = node
+		c.AddNode(id, node)
+	}
+
+	return c, nodes
+}
+
+func TestShardedCache_GetSet(t *testing.T) {
+	c, _ := newCache(3)
+
+	_, err := c.Get("missing")
+	assert.Error(t, err)
+
+	assert.NoError(t, c.Set("key", []byte("value"), time.Minute))
+
+	val, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), val)
+}
+
+func TestShardedCache_NoNodes(t *testing.T) {
+	c := New()
+
+	_, err := c.Get("key")
+	assert.ErrorIs(t, err, ErrNoNode)
+
+	err = c.Set("key", []byte("value"), time.Minute)
+	assert.ErrorIs(t, err, ErrNoNode)
+}
+
+func TestShardedCache_MGetMDelBatchesPerNode(t *testing.T) {
+	c, nodes := newCache(5)
+
+	keys := make([]string, 0, 200)
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		keys = append(keys, key)
+		assert.NoError(t, c.Set(key, []byte(key), time.Minute))
+	}
+
+	got, err := c.MGet(keys...)
+	assert.NoError(t, err)
+	assert.Equal(t, len(keys), len(got))
+
+	for _, key := range keys {
+		assert.Equal(t, []byte(key), got[key])
+	}
+
+	assert.NoError(t, c.MDel(keys...))
+
+	var totalDelCalls int
+	for _, node := range nodes {
+		// No node should have been called more than once, however many of
+		// the deleted keys it owns.
+		assert.True(t, node.delCalls <= 1)
+		totalDelCalls += node.delCalls
+	}
+
+	assert.True(t, totalDelCalls > 0)
+	assert.True(t, totalDelCalls <= len(nodes))
+
+	for _, key := range keys {
+		_, err := c.Get(key)
+		assert.Error(t, err)
+	}
+}
+
+func TestShardedCache_MembershipChangeMovesMinimalKeys(t *testing.T) {
+	c, _ := newCache(10)
+
+	const keyCount = 1000
+
+	keys := make([]string, keyCount)
+	before := make(map[string]string, keyCount)
+
+	for i := 0; i < keyCount; i++ {
+		keys[i] = fmt.Sprintf("key-%d", i)
+
+		node, err := c.nodeFor(keys[i])
+		assert.NoError(t, err)
+		before[keys[i]] = node.(*mockNode).id
+	}
+
+	c.AddNode("node-10", newMockNode("node-10"))
+
+	var moved int
+
+	for _, key := range keys {
+		node, err := c.nodeFor(key)
+		assert.NoError(t, err)
+
+		if node.(*mockNode).id != before[key] {
+			moved++
+		}
+	}
+
+	ratio := float64(moved) / float64(keyCount)
+	assert.True(t, ratio < 0.35, fmt.Sprintf("moved ratio too high: %f", ratio))
+}
+
+func TestShardedCache_AddNodeReplacesExisting(t *testing.T) {
+	c := New()
+	first := newMockNode("node")
+	second := newMockNode("node")
+
+	c.AddNode("node", first)
+	c.AddNode("node", second)
+
+	assert.NoError(t, second.Set("key", []byte("value"), time.Minute))
+
+	val, err := c.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), val)
+
+	_, err = first.Get("key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,298 @@
+package microhash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+const (
+	snapshotMagic   = "MHSS"
+	snapshotVersion = 1
+)
+
+var (
+	// ErrUnknownHashFunc is returned by LoadSnapshot when the snapshot was
+	// built with a custom hash function that hasn't been registered in this
+	// process via RegisterHashFunc.
+	ErrUnknownHashFunc = errors.New("microhash: snapshot uses an unregistered hash function")
+
+	// ErrInvalidSnapshot is returned by LoadSnapshot when b isn't a
+	// recognizable snapshot: bad magic, unsupported version, or truncated.
+	ErrInvalidSnapshot = errors.New("microhash: invalid snapshot")
+
+	hashFuncLock       sync.RWMutex
+	hashFuncNamesByPtr = map[uintptr]string{}
+	hashFuncsByName    = map[string]Func{}
+)
+
+func init() {
+	RegisterHashFunc("crc64", hashValue)
+}
+
+// RegisterHashFunc associates fn with name so that a Snapshot taken with fn
+// records name as its hash function identifier, and so LoadSnapshot can
+// resolve that name back to a Func. The default hash used by New and by
+// NewWithCustomHash(replicas, nil) is pre-registered as "crc64"; register
+// any custom Func passed to NewWithCustomHash before snapshotting a ring
+// that uses it, under the same name in every process that will load it.
+func RegisterHashFunc(name string, fn Func) {
+	hashFuncLock.Lock()
+	defer hashFuncLock.Unlock()
+
+	hashFuncNamesByPtr[reflect.ValueOf(fn).Pointer()] = name
+	hashFuncsByName[name] = fn
+}
+
+// Snapshot serializes the ring into a versioned binary blob: the configured
+// replica count, the hash function identifier, the node list with weights,
+// and the precomputed sorted keys and ring itself. LoadSnapshot rebuilds an
+// identical ConsistentHash from the blob without re-hashing any virtual
+// nodes, so Get returns the same node it would have before the snapshot was
+// taken. Nodes are recorded by their repr() string; LoadSnapshot restores
+// them as plain strings rather than their original concrete type, so this
+// round-trips cleanly for the common case of string node IDs.
+func (h *ConsistentHash) Snapshot() ([]byte, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	name, ok := hashFuncName(h.hashFunc)
+	if !ok {
+		return nil, errors.New("microhash: hash function not registered, call RegisterHashFunc before snapshotting")
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(snapshotMagic)
+	buf.WriteByte(snapshotVersion)
+	writeString(&buf, name)
+	writeUint32(&buf, uint32(h.replicas))
+	writeFloat64(&buf, h.maxLoadFactor)
+
+	nodeReprs := make([]string, 0, len(h.nodes))
+	for nodeRepr := range h.nodes {
+		nodeReprs = append(nodeReprs, nodeRepr)
+	}
+
+	sort.Strings(nodeReprs)
+
+	nodeIndex := make(map[string]uint32, len(nodeReprs))
+	writeUint32(&buf, uint32(len(nodeReprs)))
+
+	for i, nodeRepr := range nodeReprs {
+		nodeIndex[nodeRepr] = uint32(i)
+		writeString(&buf, nodeRepr)
+		writeUint32(&buf, uint32(h.weights[nodeRepr]))
+	}
+
+	writeUint32(&buf, uint32(len(h.keys)))
+
+	for _, key := range h.keys {
+		writeUint64(&buf, key)
+
+		slot := h.ring[key]
+		writeUint32(&buf, uint32(len(slot)))
+
+		for _, node := range slot {
+			writeUint32(&buf, nodeIndex[repr(node)])
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// LoadSnapshot rebuilds a ConsistentHash from a blob produced by Snapshot.
+// It returns ErrInvalidSnapshot if b isn't a recognizable snapshot, and
+// ErrUnknownHashFunc if it was built with a custom hash function that
+// hasn't been registered in this process via RegisterHashFunc.
+func LoadSnapshot(b []byte) (*ConsistentHash, error) {
+	r := bytes.NewReader(b)
+
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != snapshotMagic {
+		return nil, ErrInvalidSnapshot
+	}
+
+	version, err := r.ReadByte()
+	if err != nil || version != snapshotVersion {
+		return nil, ErrInvalidSnapshot
+	}
+
+	name, err := readString(r)
+	if err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	fn, ok := lookupHashFunc(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownHashFunc, name)
+	}
+
+	replicas, err := readUint32(r)
+	if err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	maxLoadFactor, err := readFloat64(r)
+	if err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	nodeCount, err := readUint32(r)
+	if err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	nodes := make([]any, nodeCount)
+	nodeSet := make(map[string]struct{}, nodeCount)
+	weights := make(map[string]int, nodeCount)
+	loads := make(map[string]*int64, nodeCount)
+
+	for i := range nodes {
+		nodeRepr, err := readString(r)
+		if err != nil {
+			return nil, ErrInvalidSnapshot
+		}
+
+		weight, err := readUint32(r)
+		if err != nil {
+			return nil, ErrInvalidSnapshot
+		}
+
+		nodes[i] = nodeRepr
+		nodeSet[nodeRepr] = struct{}{}
+		weights[nodeRepr] = int(weight)
+		loads[nodeRepr] = new(int64)
+	}
+
+	keyCount, err := readUint32(r)
+	if err != nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	keys := make([]uint64, keyCount)
+	ring := make(map[uint64][]any, keyCount)
+
+	for i := range keys {
+		key, err := readUint64(r)
+		if err != nil {
+			return nil, ErrInvalidSnapshot
+		}
+
+		slotSize, err := readUint32(r)
+		if err != nil {
+			return nil, ErrInvalidSnapshot
+		}
+
+		slot := make([]any, slotSize)
+
+		for j := range slot {
+			idx, err := readUint32(r)
+			if err != nil || idx >= uint32(len(nodes)) {
+				return nil, ErrInvalidSnapshot
+			}
+
+			slot[j] = nodes[idx]
+		}
+
+		keys[i] = key
+		ring[key] = slot
+	}
+
+	return &ConsistentHash{
+		hashFunc:      fn,
+		replicas:      int(replicas),
+		maxLoadFactor: maxLoadFactor,
+		keys:          keys,
+		ring:          ring,
+		nodes:         nodeSet,
+		weights:       weights,
+		loads:         loads,
+	}, nil
+}
+
+func hashFuncName(fn Func) (string, bool) {
+	hashFuncLock.RLock()
+	defer hashFuncLock.RUnlock()
+
+	name, ok := hashFuncNamesByPtr[reflect.ValueOf(fn).Pointer()]
+
+	return name, ok
+}
+
+func lookupHashFunc(name string) (Func, bool) {
+	hashFuncLock.RLock()
+	defer hashFuncLock.RUnlock()
+
+	fn, ok := hashFuncsByName[name]
+
+	return fn, ok
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	writeUint32(buf, uint32(len(s)))
+	buf.WriteString(s)
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+func readUint32(r *bytes.Reader) (uint32, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(b[:]), nil
+}
+
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	buf.Write(b[:])
+}
+
+func readUint64(r *bytes.Reader) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+func writeFloat64(buf *bytes.Buffer, v float64) {
+	writeUint64(buf, math.Float64bits(v))
+}
+
+func readFloat64(r *bytes.Reader) (float64, error) {
+	bits, err := readUint64(r)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Float64frombits(bits), nil
+}
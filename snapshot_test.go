@@ -0,0 +1,141 @@
+package microhash
+
+import (
+	"bytes"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHash_SnapshotRoundTrip(t *testing.T) {
+	ch := New(WithMaxLoadFactor(1.25))
+	for i := 0; i < keySize; i++ {
+		ch.Add(localhostPrefix + strconv.Itoa(i))
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	keys := make([]int, 10000)
+	want := make([]any, len(keys))
+
+	for i := range keys {
+		keys[i] = rnd.Int()
+
+		node, ok := ch.Get(keys[i])
+		assert.True(t, ok)
+		want[i] = node
+	}
+
+	b, err := ch.Snapshot()
+	assert.NoError(t, err)
+
+	restored, err := LoadSnapshot(b)
+	assert.NoError(t, err)
+
+	for i, key := range keys {
+		node, ok := restored.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, want[i], node)
+	}
+}
+
+func TestConsistentHash_SnapshotRestoresWeights(t *testing.T) {
+	ch := New()
+	ch.AddWithWeight("a", 20)
+	ch.AddWithWeight("b", topWeight)
+
+	b, err := ch.Snapshot()
+	assert.NoError(t, err)
+
+	restored, err := LoadSnapshot(b)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ch.weights["a"], restored.weights["a"])
+	assert.Equal(t, ch.weights["b"], restored.weights["b"])
+}
+
+func TestConsistentHash_SnapshotCustomHashRoundTrip(t *testing.T) {
+	fn := func(data []byte) uint64 {
+		h := fnv.New64a()
+		h.Write(data)
+
+		return h.Sum64()
+	}
+
+	RegisterHashFunc("test-fnv1a", fn)
+
+	ch := NewWithCustomHash(minReplicas, fn)
+	for i := 0; i < keySize; i++ {
+		ch.Add(localhostPrefix + strconv.Itoa(i))
+	}
+
+	b, err := ch.Snapshot()
+	assert.NoError(t, err)
+
+	restored, err := LoadSnapshot(b)
+	assert.NoError(t, err)
+
+	for i := 0; i < requestSize; i++ {
+		want, ok := ch.Get(i)
+		assert.True(t, ok)
+
+		got, ok := restored.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestConsistentHash_SnapshotUnregisteredHashFunc(t *testing.T) {
+	fn := func(data []byte) uint64 {
+		h := fnv.New64a()
+		h.Write(data)
+
+		return h.Sum64()
+	}
+
+	ch := NewWithCustomHash(minReplicas, fn)
+	ch.Add("node")
+
+	_, err := ch.Snapshot()
+	assert.Error(t, err)
+}
+
+func TestLoadSnapshot_RejectsUnknownHashFunc(t *testing.T) {
+	var buf bytes.Buffer
+
+	buf.WriteString(snapshotMagic)
+	buf.WriteByte(snapshotVersion)
+	writeString(&buf, "not-a-real-hash")
+	writeUint32(&buf, minReplicas)
+	writeFloat64(&buf, 0)
+	writeUint32(&buf, 0)
+	writeUint32(&buf, 0)
+
+	_, err := LoadSnapshot(buf.Bytes())
+	assert.ErrorIs(t, err, ErrUnknownHashFunc)
+}
+
+func TestLoadSnapshot_RejectsBadMagicAndVersion(t *testing.T) {
+	_, err := LoadSnapshot([]byte("not a snapshot"))
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+
+	var buf bytes.Buffer
+	buf.WriteString(snapshotMagic)
+	buf.WriteByte(snapshotVersion + 1)
+
+	_, err = LoadSnapshot(buf.Bytes())
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+}
+
+func TestLoadSnapshot_RejectsTruncatedData(t *testing.T) {
+	ch := New()
+	ch.Add("node")
+
+	b, err := ch.Snapshot()
+	assert.NoError(t, err)
+
+	_, err = LoadSnapshot(b[:len(b)-10])
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+}
@@ -0,0 +1,21 @@
+package microhash
+
+// Strategy is implemented by the hashing backends that map keys onto nodes.
+// ConsistentHash (ring-based) and RendezvousHash (highest random weight) are
+// the two implementations; callers that only need Add/Remove/Get can depend
+// on Strategy instead of a concrete type to swap between them.
+type Strategy interface {
+	// Add adds a node with the full weight.
+	Add(node any)
+	// AddWithWeight adds a node with a weight between 1 and topWeight.
+	AddWithWeight(node any, weight int)
+	// Remove removes a node.
+	Remove(node any)
+	// Get returns the node a key maps to, or ok=false if there are no nodes.
+	Get(key any) (node any, ok bool)
+}
+
+var (
+	_ Strategy = (*ConsistentHash)(nil)
+	_ Strategy = (*RendezvousHash)(nil)
+)
@@ -0,0 +1,100 @@
+package microhash
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// strategyEntropy feeds requestSize keys through s and returns the entropy of
+// the resulting node distribution, reusing calcEntropy from
+// consistenthash_test.go so ConsistentHash and RendezvousHash are held to the
+// same bar.
+func strategyEntropy(t *testing.T, s Strategy, prefix string) float64 {
+	for i := 0; i < keySize; i++ {
+		s.Add(prefix + strconv.Itoa(i))
+	}
+
+	counts := make(map[any]int)
+
+	for i := 0; i < requestSize; i++ {
+		node, ok := s.Get(requestSize + i)
+		assert.True(t, ok)
+		counts[node]++
+	}
+
+	return calcEntropy(counts)
+}
+
+// strategyKeys returns the node each of requestSize keys maps to on s.
+func strategyKeys(t *testing.T, s Strategy) map[int]any {
+	keys := make(map[int]any, requestSize)
+
+	for i := 0; i < requestSize; i++ {
+		node, ok := s.Get(requestSize + i)
+		assert.True(t, ok)
+		keys[i] = node
+	}
+
+	return keys
+}
+
+func TestStrategy_Entropy(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Strategy
+	}{
+		{"ConsistentHash", New()},
+		{"RendezvousHash", NewRendezvous()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entropy := strategyEntropy(t, tt.s, localhostPrefix)
+			assert.True(t, entropy > .95)
+		})
+	}
+}
+
+func TestStrategy_TransferOnFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		make func() Strategy
+	}{
+		{"ConsistentHash", func() Strategy { return New() }},
+		{"RendezvousHash", func() Strategy { return NewRendezvous() }},
+	}
+
+	const index = 11
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.make()
+			for i := 0; i < keySize; i++ {
+				s.Add(localhostPrefix + strconv.Itoa(i))
+			}
+
+			before := strategyKeys(t, s)
+
+			remove := fmt.Sprintf("%s%d", localhostPrefix, index)
+			s.Remove(remove)
+
+			after := strategyKeys(t, s)
+
+			var transferred int
+
+			for k, v := range after {
+				assert.NotEqual(t, remove, v)
+
+				if v != before[k] {
+					transferred++
+				}
+			}
+
+			ratio := float32(transferred) / float32(requestSize)
+			assert.True(t, ratio < 2.5/float32(keySize), fmt.Sprintf("%s: %f", tt.name, ratio))
+		})
+	}
+}